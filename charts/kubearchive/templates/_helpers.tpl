@@ -5,14 +5,65 @@ SPDX-License-Identifier: Apache-2.0
 
 {{/*
 Create environment variables for OpenTelemetry if .observability is set to true. Otherwise set KUBEARCHIVE_OTEL_ENABLED=false.
-This tells the OpenTelemtry instrumentation if it should start or not.
+This tells the OpenTelemtry instrumentation if it should start or not. The exporter, sampler and resource attributes
+are taken from .otel.
 */}}
 {{- define "kubearchive.v1.otel.env" -}}
 {{- if .observability -}}
 - name: KUBEARCHIVE_OTEL_ENABLED
   value: "true"
+{{- with .otel.endpoint }}
+- name: OTEL_EXPORTER_OTLP_ENDPOINT
+  value: {{ . | quote }}
+{{- end }}
+{{- with .otel.sampler }}
+- name: OTEL_TRACES_SAMPLER
+  value: {{ . | quote }}
+{{- end }}
+{{- with .otel.samplerArg }}
+- name: OTEL_TRACES_SAMPLER_ARG
+  value: {{ . | quote }}
+{{- end }}
+{{- with .otel.resourceAttributes }}
+- name: OTEL_RESOURCE_ATTRIBUTES
+  value: {{ . | quote }}
+{{- end }}
 {{- else -}}
 - name: KUBEARCHIVE_OTEL_ENABLED
   value: "false"
 {{- end -}}
 {{- end -}}
+
+{{/*
+Create a HorizontalPodAutoscaler for the Deployment named .name using the .autoscaling values of a component.
+*/}}
+{{- define "kubearchive.v1.hpa" -}}
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{ .name }}
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: {{ .name }}
+  minReplicas: {{ .autoscaling.minReplicas }}
+  maxReplicas: {{ .autoscaling.maxReplicas }}
+  metrics:
+    {{- if .autoscaling.targetCPUUtilizationPercentage }}
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: {{ .autoscaling.targetCPUUtilizationPercentage }}
+    {{- end }}
+    {{- if .autoscaling.targetMemoryUtilizationPercentage }}
+    - type: Resource
+      resource:
+        name: memory
+        target:
+          type: Utilization
+          averageUtilization: {{ .autoscaling.targetMemoryUtilizationPercentage }}
+    {{- end }}
+{{- end -}}
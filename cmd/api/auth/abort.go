@@ -5,11 +5,13 @@ package auth
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/kubearchive/kubearchive/cmd/api/requestid"
 	"log"
 )
 
 func abort(c *gin.Context, msg string, code int) {
-	log.Println(msg)
-	c.JSON(code, gin.H{"message": msg})
+	id := requestid.Get(c)
+	log.Printf("[%s] %s", id, msg)
+	c.JSON(code, gin.H{"message": msg, "requestID": id})
 	c.Abort()
 }
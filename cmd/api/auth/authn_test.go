@@ -5,7 +5,9 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"github.com/kubearchive/kubearchive/cmd/api/requestid"
 	"github.com/stretchr/testify/assert"
 	apiAuthnv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -106,3 +108,22 @@ func TestAuthentication(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticationErrorRequestID(t *testing.T) {
+	ftr := &fakeTokenReview{authenticated: false}
+	router := gin.New()
+	router.Use(requestid.RequestID(), Authentication(ftr))
+	router.GET("/", func(c *gin.Context) {})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Add("Authorization", "Bearer faketoken")
+	req.Header.Add(requestid.Header, "fake-request-id")
+	router.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+	assert.Equal(t, "fake-request-id", res.Header().Get(requestid.Header))
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	assert.Equal(t, "fake-request-id", body["requestID"])
+}
@@ -4,19 +4,33 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/kubearchive/kubearchive/cmd/api/auth"
+	"github.com/kubearchive/kubearchive/cmd/api/requestid"
 	"github.com/kubearchive/kubearchive/cmd/api/routers"
 	"github.com/kubearchive/kubearchive/pkg/observability"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+const (
+	tlsCertFile = "/etc/kubearchive/ssl/tls.crt"
+	tlsKeyFile  = "/etc/kubearchive/ssl/tls.key"
+)
+
 type Server struct {
 	k8sClient kubernetes.Interface
 	router    *gin.Engine
@@ -37,6 +51,7 @@ func getKubernetesClient() *kubernetes.Clientset {
 func NewServer(k8sClient kubernetes.Interface) *Server {
 	router := gin.Default()
 	router.Use(otelgin.Middleware("kubearchive.api"))
+	router.Use(requestid.RequestID())
 	router.Use(auth.Authentication(k8sClient.AuthenticationV1().TokenReviews()))
 	router.Use(auth.RBACAuthorization(k8sClient.AuthorizationV1().SubjectAccessReviews()))
 	router.GET("/apis/:group/:version/:resourceType", routers.GetAllResources)
@@ -53,8 +68,40 @@ func main() {
 		log.Printf("Could not start opentelemetry: %s", err)
 	}
 
+	// The certificate is rotated by cert-manager, reload it when the mounted secret changes
+	watcher, err := certwatcher.New(tlsCertFile, tlsKeyFile)
+	if err != nil {
+		log.Fatalf("Could not load TLS certificate: %s", err)
+	}
+	go func() {
+		if err := watcher.Start(context.Background()); err != nil {
+			log.Printf("Could not watch TLS certificate: %s", err)
+		}
+	}()
+	// Also reload it on SIGHUP, for tools that signal the process instead of relying on the file watch
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for range hangup {
+			if err := watcher.ReadCertificate(); err != nil {
+				log.Printf("Could not reload TLS certificate: %s", err)
+				continue
+			}
+			log.Println("Reloaded TLS certificate")
+		}
+	}()
+
 	server := NewServer(getKubernetesClient())
-	err = server.router.RunTLS("localhost:8081", "/etc/kubearchive/ssl/tls.crt", "/etc/kubearchive/ssl/tls.key")
+	httpServer := &http.Server{
+		Addr:              "localhost:8081",
+		Handler:           server.router,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: watcher.GetCertificate,
+		},
+	}
+	err = httpServer.ListenAndServeTLS("", "")
 	if err != nil {
 		log.Printf("Could not run server on localhost: %s", err)
 	}
@@ -33,6 +33,7 @@ func TestOtelMiddlewareConfigured(t *testing.T) {
 	// The full handler names may be different when running in debug mode
 	expectedNames := []string{
 		"otelgin.Middleware",
+		"RequestID",
 		"Authentication",
 		"RBACAuthorization",
 	}
@@ -0,0 +1,54 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package requestid
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// Header is the HTTP header carrying the request ID, both in requests and responses
+	Header = "X-Request-ID"
+	// maxLength is the maximum length of a request ID provided by the caller
+	maxLength  = 128
+	contextKey = "requestID"
+)
+
+// valid reports if a request ID provided by the caller can be used as is. IDs are written to logs, so only
+// printable ASCII characters without spaces are allowed.
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestID makes sure every request has an ID. It uses the ID given by the caller in the X-Request-ID header, or
+// generates a new one if there is none or it is not valid. The ID is stored in the context, returned in the
+// X-Request-ID header of the response and added to the span of the request.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if !valid(id) {
+			id = uuid.NewString()
+		}
+		c.Set(contextKey, id)
+		c.Header(Header, id)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("http.request_id", id))
+		c.Next()
+	}
+}
+
+// Get returns the ID of the request, or an empty string if the RequestID middleware did not run
+func Get(c *gin.Context) string {
+	return c.GetString(contextKey)
+}
@@ -0,0 +1,66 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		expected string
+	}{
+		{
+			name:     "id given by the caller",
+			given:    "fake-request-id",
+			expected: "fake-request-id",
+		},
+		{
+			name:  "no id given",
+			given: "",
+		},
+		{
+			name:  "id with spaces",
+			given: "fake request id",
+		},
+		{
+			name:  "id too long",
+			given: strings.Repeat("a", maxLength+1),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(res)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+			if tc.given != "" {
+				c.Request.Header.Set(Header, tc.given)
+			}
+			RequestID()(c)
+
+			id := Get(c)
+			assert.Equal(t, res.Header().Get(Header), id)
+			if tc.expected != "" {
+				assert.Equal(t, tc.expected, id)
+			} else {
+				_, err := uuid.Parse(id)
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetWithoutMiddleware(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Equal(t, "", Get(c))
+}
@@ -0,0 +1,130 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	eventSource = "kubearchive.org/etcd-import"
+	// eventType matches the type of the CloudEvents sent by ApiServerSource when a resource is added
+	eventType = "dev.knative.apiserver.resource.add"
+)
+
+var logger = log.New(os.Stderr, "", log.LstdFlags|log.Lmicroseconds|log.LUTC)
+
+// readObjects reads the resources in a JSON dump. The dump can hold a single resource or a List of resources,
+// as written by kubectl get -o json.
+func readObjects(data []byte) ([]unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(data, &obj.Object); err != nil {
+		return nil, fmt.Errorf("could not decode resources: %w", err)
+	}
+	if !obj.IsList() {
+		return []unstructured.Unstructured{*obj}, nil
+	}
+
+	objects := []unstructured.Unstructured{}
+	err := obj.EachListItem(func(item runtime.Object) error {
+		objects = append(objects, *item.(*unstructured.Unstructured))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// newEvent wraps obj in a CloudEvent like the ones ApiServerSource sends in Resource mode. The event id is built
+// from the uid and resourceVersion of obj, so importing the same dump twice produces the same events. Objects
+// without them, like hand-written ones, get a random id so their events are not taken as duplicates.
+func newEvent(obj unstructured.Unstructured) (cloudevents.Event, error) {
+	event := cloudevents.NewEvent()
+	if obj.GetUID() != "" && obj.GetResourceVersion() != "" {
+		event.SetID(fmt.Sprintf("%s-%s", obj.GetUID(), obj.GetResourceVersion()))
+	} else {
+		event.SetID(uuid.NewString())
+	}
+	event.SetSource(eventSource)
+	event.SetType(eventType)
+	event.SetExtension("kind", obj.GetKind())
+	event.SetExtension("name", obj.GetName())
+	event.SetExtension("namespace", obj.GetNamespace())
+	err := event.SetData(cloudevents.ApplicationJSON, obj.Object)
+	return event, err
+}
+
+func main() {
+	var file string
+	var veleroBackup string
+	var sink string
+	flag.StringVar(&file, "file", "", "Path to a JSON dump of resources, as written by kubectl get -o json")
+	flag.StringVar(&veleroBackup, "velero-backup", "", "Path to a Velero backup tarball, as written by velero backup download")
+	flag.StringVar(&sink, "sink", "http://kubearchive-sink.kubearchive.svc.cluster.local", "URL of the KubeArchive sink")
+	flag.Parse()
+
+	var objects []unstructured.Unstructured
+	switch {
+	case file != "" && veleroBackup != "":
+		logger.Fatalln("only one of --file and --velero-backup can be set")
+	case file != "":
+		data, err := os.ReadFile(file) // #nosec G304
+		if err != nil {
+			logger.Fatalf("could not read %s: %s\n", file, err.Error())
+		}
+		objects, err = readObjects(data)
+		if err != nil {
+			logger.Fatalf("could not read resources from %s: %s\n", file, err.Error())
+		}
+	case veleroBackup != "":
+		f, err := os.Open(veleroBackup) // #nosec G304
+		if err != nil {
+			logger.Fatalf("could not open %s: %s\n", veleroBackup, err.Error())
+		}
+		objects, err = readVeleroBackup(f)
+		f.Close()
+		if err != nil {
+			logger.Fatalf("could not read resources from %s: %s\n", veleroBackup, err.Error())
+		}
+	default:
+		logger.Fatalln("one of --file and --velero-backup is required")
+	}
+
+	client, err := cloudevents.NewClientHTTP()
+	if err != nil {
+		logger.Fatalf("failed to create CloudEvents HTTP client: %s\n", err.Error())
+	}
+	ctx := cloudevents.ContextWithTarget(context.Background(), sink)
+
+	failed := 0
+	for _, obj := range objects {
+		event, err := newEvent(obj)
+		if err != nil {
+			logger.Printf("could not create CloudEvent for %s %s/%s: %s\n", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err.Error())
+			failed++
+			continue
+		}
+		result := client.Send(ctx, event)
+		if !cloudevents.IsACK(result) {
+			logger.Printf("could not send %s %s/%s: %s\n", obj.GetKind(), obj.GetNamespace(), obj.GetName(), result.Error())
+			failed++
+		}
+	}
+
+	logger.Printf("imported %d of %d resources\n", len(objects)-failed, len(objects))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	podJSON = `{"apiVersion": "v1", "kind": "Pod",
+		"metadata": {"name": "pod-1", "namespace": "test", "uid": "1234", "resourceVersion": "42"}}`
+	listJSON = `{"apiVersion": "v1", "kind": "List", "items": [
+		{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "pod-1", "namespace": "test"}},
+		{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"name": "job-1", "namespace": "test"}}]}`
+)
+
+func TestReadObjects(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []string
+	}{
+		{
+			name:     "single resource",
+			data:     podJSON,
+			expected: []string{"pod-1"},
+		},
+		{
+			name:     "list of resources",
+			data:     listJSON,
+			expected: []string{"pod-1", "job-1"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			objects, err := readObjects([]byte(tc.data))
+			assert.NoError(t, err)
+			names := []string{}
+			for _, obj := range objects {
+				names = append(names, obj.GetName())
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestReadObjectsInvalid(t *testing.T) {
+	_, err := readObjects([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestNewEvent(t *testing.T) {
+	objects, err := readObjects([]byte(podJSON))
+	assert.NoError(t, err)
+
+	event, err := newEvent(objects[0])
+	assert.NoError(t, err)
+	assert.NoError(t, event.Validate())
+	assert.Equal(t, "1234-42", event.ID())
+	assert.Equal(t, eventType, event.Type())
+	assert.Equal(t, "Pod", event.Extensions()["kind"])
+	assert.Equal(t, "pod-1", event.Extensions()["name"])
+	assert.Equal(t, "test", event.Extensions()["namespace"])
+	assert.JSONEq(t, podJSON, string(event.Data()))
+}
+
+func TestNewEventWithoutUID(t *testing.T) {
+	objects, err := readObjects([]byte(listJSON))
+	assert.NoError(t, err)
+
+	ids := map[string]bool{}
+	for _, obj := range objects {
+		event, err := newEvent(obj)
+		assert.NoError(t, err)
+		assert.NoError(t, event.Validate())
+		_, err = uuid.Parse(event.ID())
+		assert.NoError(t, err)
+		ids[event.ID()] = true
+	}
+	assert.Len(t, ids, len(objects))
+}
@@ -0,0 +1,64 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// isVeleroResource reports if name is the path of a resource in a Velero backup. Resources are stored as
+// resources/<resource>/namespaces/<namespace>/<name>.json or resources/<resource>/cluster/<name>.json. Backups
+// taken with API group versions enabled also store a copy per version under resources/<resource>/<version>,
+// but the preferred version is always written to the paths above too, so only those are kept.
+func isVeleroResource(name string) bool {
+	parts := strings.Split(path.Clean(name), "/")
+	if len(parts) < 4 || parts[0] != "resources" || path.Ext(name) != ".json" {
+		return false
+	}
+	return parts[2] == "namespaces" || parts[2] == "cluster"
+}
+
+// readVeleroBackup reads the resources in a Velero backup tarball, as downloaded with velero backup download.
+// The resources keep the metadata they had when the backup was taken, including their timestamps.
+func readVeleroBackup(r io.Reader) ([]unstructured.Unstructured, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not decompress backup: %w", err)
+	}
+	defer gz.Close()
+
+	objects := []unstructured.Unstructured{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read backup: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || !isVeleroResource(header.Name) {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", header.Name, err)
+		}
+		read, err := readObjects(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", header.Name, err)
+		}
+		objects = append(objects, read...)
+	}
+	return objects, nil
+}
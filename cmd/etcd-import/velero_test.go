@@ -0,0 +1,80 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func veleroBackup(t *testing.T, files map[string]string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		assert.NoError(t, err)
+		_, err = tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	return buf
+}
+
+func TestIsVeleroResource(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "resources/pods/namespaces/test/pod-1.json", expected: true},
+		{name: "resources/namespaces/cluster/test.json", expected: true},
+		{name: "resources/pods/v1-preferredversion/namespaces/test/pod-1.json", expected: false},
+		{name: "resources/jobs.batch/v1/namespaces/test/job-1.json", expected: false},
+		{name: "metadata/version", expected: false},
+		{name: "resources/pods/namespaces/test/pod-1.yaml", expected: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isVeleroResource(tc.name))
+		})
+	}
+}
+
+func TestReadVeleroBackup(t *testing.T) {
+	backup := veleroBackup(t, map[string]string{
+		"metadata/version":                          "1",
+		"resources/pods/namespaces/test/pod-1.json": podJSON,
+	})
+
+	objects, err := readVeleroBackup(backup)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, "pod-1", objects[0].GetName())
+	assert.Equal(t, "test", objects[0].GetNamespace())
+}
+
+func TestReadVeleroBackupWithGroupVersions(t *testing.T) {
+	backup := veleroBackup(t, map[string]string{
+		"metadata/version":                                              "1",
+		"resources/pods/namespaces/test/pod-1.json":                     podJSON,
+		"resources/pods/v1-preferredversion/namespaces/test/pod-1.json": podJSON,
+		"resources/pods/v2/namespaces/test/pod-1.json":                  podJSON,
+	})
+
+	objects, err := readVeleroBackup(backup)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, "pod-1", objects[0].GetName())
+	assert.Equal(t, "test", objects[0].GetNamespace())
+}
+
+func TestReadVeleroBackupInvalid(t *testing.T) {
+	_, err := readVeleroBackup(bytes.NewBufferString("not a tarball"))
+	assert.Error(t, err)
+}
@@ -7,13 +7,42 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// PausedAnnotation stops archiving the namespace of a KubeArchiveConfig when set to "true"
+	PausedAnnotation = "kubearchive.org/paused"
+	// InstanceLabel selects the KubeArchive installation that reconciles a KubeArchiveConfig, when
+	// there is more than one in the cluster
+	InstanceLabel = "kubearchive.org/instance"
+	// ResourcesServedCondition is true when the cluster serves all the resources a KubeArchiveConfig archives
+	ResourcesServedCondition = "ResourcesServed"
+)
+
 // KubeArchiveConfigSpec defines the desired state of KubeArchiveConfig
 type KubeArchiveConfigSpec struct {
 	Filter string `json:"filter,omitempty"`
+	// Preset is the name of a predefined list of resources to archive. The resources
+	// of the preset are archived in addition to the ones listed in Resources.
+	//+kubebuilder:validation:Enum=tekton;argo-workflows;batch-jobs
+	Preset string `json:"preset,omitempty"`
+	// Resources is the list of resources to archive.
+	Resources []KubeArchiveConfigResource `json:"resources,omitempty"`
+}
+
+// KubeArchiveConfigResource identifies a kind of resource to archive
+type KubeArchiveConfigResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// LabelSelector restricts the resources archived to the ones matching it. All the resources of the
+	// kind are archived when it is not set.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
 }
 
 // KubeArchiveConfigStatus defines the observed state of KubeArchiveConfig
 type KubeArchiveConfigStatus struct {
+	// Conditions describe the state of the resources archived for the KubeArchiveConfig.
+	//+listType=map
+	//+listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -5,6 +5,10 @@ package controller
 
 import (
 	"context"
+	goerrors "errors"
+	"fmt"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -12,20 +16,44 @@ import (
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	kubearchivev1alpha1 "github.com/kubearchive/kubearchive/cmd/operator/api/v1alpha1"
 )
 
+const (
+	kubeArchiveConfigControllerName = "kubearchiveconfig"
+	// unservedResourcesRequeue is how often a KubeArchiveConfig with resources the cluster does not serve is
+	// reconciled again, to archive them once their CRDs are installed
+	unservedResourcesRequeue = time.Minute
+	// DefaultSinkName and DefaultSinkNamespace identify the sink Service installed by the KubeArchive chart
+	DefaultSinkName      = "kubearchive-sink"
+	DefaultSinkNamespace = "kubearchive"
+)
+
 // KubeArchiveConfigReconciler reconciles a KubeArchiveConfig object
 type KubeArchiveConfigReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// SinkName and SinkNamespace identify the Service of the sink the ApiServerSources send events to.
+	// DefaultSinkName and DefaultSinkNamespace are used when they are empty.
+	SinkName      string
+	SinkNamespace string
+	// Instance is the value of the kubearchive.org/instance label of the KubeArchiveConfigs to reconcile.
+	// When empty, only KubeArchiveConfigs without the label are reconciled.
+	Instance string
+
+	sources activeSources
 }
 
 //+kubebuilder:rbac:groups=kubearchive.kubearchive.org,resources=kubearchiveconfigs,verbs=get;list;watch;create;update;patch;delete
@@ -45,24 +73,72 @@ func (r *KubeArchiveConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	if err != nil {
 		if errors.IsNotFound(err) {
 			log.Info("KubeArchiveConfig resource not found. Ignoring since object must have been deleted.")
+			r.sources.set(req.NamespacedName, false)
 			return ctrl.Result{}, nil
 		}
 		log.Error(err, "Failed to get KubeArchiveConfig, requeuing the request.")
 		return ctrl.Result{}, err
 	}
+	// The watches on the owned resources queue their KubeArchiveConfig whatever installation it belongs to. It may
+	// also have just been handed over to another installation, so it no longer counts as an active source here.
+	if !r.ownsConfig(kaconfig) {
+		log.Info("KubeArchiveConfig belongs to another KubeArchive installation, ignoring it.")
+		r.sources.set(req.NamespacedName, false)
+		return ctrl.Result{}, nil
+	}
 
-	r.reconcileServiceAccount(ctx, kaconfig)
-	r.reconcileRole(ctx, kaconfig)
-	r.reconcileRoleBinding(ctx, kaconfig)
-	r.reconcileApiServerSource(ctx, kaconfig)
+	// Every owned resource is reconciled even if a previous one failed, and the errors are returned together
+	// so the request is requeued.
+	var errs []error
+	if _, err = r.reconcileServiceAccount(ctx, kaconfig); err != nil {
+		resourceReconcileErrors.WithLabelValues(kubeArchiveConfigControllerName, "ServiceAccount").Inc()
+		errs = append(errs, err)
+	}
+	resources, skipped, mappingErr := r.mappedResources(ctx, kaconfig)
+	if mappingErr != nil {
+		log.Error(mappingErr, "Unable to resolve the archived resources")
+		errs = append(errs, mappingErr)
+	} else if _, err = r.reconcileRole(ctx, kaconfig, resources); err != nil {
+		resourceReconcileErrors.WithLabelValues(kubeArchiveConfigControllerName, "Role").Inc()
+		errs = append(errs, err)
+	}
+	if _, err = r.reconcileRoleBinding(ctx, kaconfig); err != nil {
+		resourceReconcileErrors.WithLabelValues(kubeArchiveConfigControllerName, "RoleBinding").Inc()
+		errs = append(errs, err)
+	}
+	paused := kaconfig.Annotations[kubearchivev1alpha1.PausedAnnotation] == "true"
+	var sourceErr error
+	if paused {
+		log.Info("KubeArchiveConfig is paused, removing its ApiServerSource.")
+		sourceErr = r.deleteApiServerSource(ctx, kaconfig)
+	} else if mappingErr == nil {
+		_, sourceErr = r.reconcileApiServerSource(ctx, kaconfig, resources)
+	}
+	if sourceErr != nil {
+		resourceReconcileErrors.WithLabelValues(kubeArchiveConfigControllerName, "ApiServerSource").Inc()
+		errs = append(errs, sourceErr)
+	}
+	r.sources.set(req.NamespacedName, sourceErr == nil && mappingErr == nil && !paused)
 
+	if mappingErr == nil {
+		if err = r.updateResourcesCondition(ctx, kaconfig, skipped); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return ctrl.Result{}, goerrors.Join(errs...)
+	}
+	// Nothing watches for CRDs being installed, so check again later for the kinds the cluster does not serve yet
+	if len(skipped) > 0 {
+		return ctrl.Result{RequeueAfter: unservedResourcesRequeue}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *KubeArchiveConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&kubearchivev1alpha1.KubeArchiveConfig{}).
+		For(&kubearchivev1alpha1.KubeArchiveConfig{}, builder.WithPredicates(r.instancePredicate())).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&rbacv1.Role{}).
 		Owns(&rbacv1.RoleBinding{}).
@@ -70,6 +146,46 @@ func (r *KubeArchiveConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
+// ownsConfig reports if obj belongs to the KubeArchive installation of this reconciler, so installations sharing
+// a cluster do not reconcile each other's KubeArchiveConfigs.
+func (r *KubeArchiveConfigReconciler) ownsConfig(obj client.Object) bool {
+	return obj.GetLabels()[kubearchivev1alpha1.InstanceLabel] == r.Instance
+}
+
+// instancePredicate filters the KubeArchiveConfig events of other installations. Updates are let through when
+// either the old or the new object belongs to this installation, so a KubeArchiveConfig relabeled to another
+// installation is seen by the one it leaves.
+func (r *KubeArchiveConfigReconciler) instancePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return r.ownsConfig(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return r.ownsConfig(e.ObjectOld) || r.ownsConfig(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return r.ownsConfig(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return r.ownsConfig(e.Object)
+		},
+	}
+}
+
+func (r *KubeArchiveConfigReconciler) sinkName() string {
+	if r.SinkName == "" {
+		return DefaultSinkName
+	}
+	return r.SinkName
+}
+
+func (r *KubeArchiveConfigReconciler) sinkNamespace() string {
+	if r.SinkNamespace == "" {
+		return DefaultSinkNamespace
+	}
+	return r.SinkNamespace
+}
+
 func (r *KubeArchiveConfigReconciler) reconcileServiceAccount(ctx context.Context, kaconfig *kubearchivev1alpha1.KubeArchiveConfig) (*corev1.ServiceAccount, error) {
 	log := log.FromContext(ctx)
 
@@ -115,11 +231,11 @@ func (r *KubeArchiveConfigReconciler) desiredServiceAccount(kaconfig *kubearchiv
 	return sa, nil
 }
 
-func (r *KubeArchiveConfigReconciler) reconcileRole(ctx context.Context, kaconfig *kubearchivev1alpha1.KubeArchiveConfig) (*rbacv1.Role, error) {
+func (r *KubeArchiveConfigReconciler) reconcileRole(ctx context.Context, kaconfig *kubearchivev1alpha1.KubeArchiveConfig, resources []mappedResource) (*rbacv1.Role, error) {
 	log := log.FromContext(ctx)
 
 	log.Info("in reconcileRole")
-	role, err := r.desiredRole(kaconfig)
+	role, err := r.desiredRole(kaconfig, resources)
 	if err != nil {
 		log.Error(err, "Unable to get desired Role")
 		return role, err
@@ -146,17 +262,91 @@ func (r *KubeArchiveConfigReconciler) reconcileRole(ctx context.Context, kaconfi
 	return role, nil
 }
 
-func (r *KubeArchiveConfigReconciler) desiredRole(kaconfig *kubearchivev1alpha1.KubeArchiveConfig) (*rbacv1.Role, error) {
+// mappedResource is an archived resource together with its REST mapping in the cluster
+type mappedResource struct {
+	kubearchivev1alpha1.KubeArchiveConfigResource
+	mapping *meta.RESTMapping
+}
+
+// mappedResources returns the archived resources of kaconfig that exist in the cluster, and the ones that do not.
+// Kinds the cluster does not serve, like the ones of a preset whose CRDs are not installed, are skipped so they do
+// not stop the other resources from being archived.
+func (r *KubeArchiveConfigReconciler) mappedResources(ctx context.Context, kaconfig *kubearchivev1alpha1.KubeArchiveConfig) ([]mappedResource, []kubearchivev1alpha1.KubeArchiveConfigResource, error) {
+	log := log.FromContext(ctx)
+
+	resources, err := archivedResources(kaconfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mapped := []mappedResource{}
+	skipped := []kubearchivev1alpha1.KubeArchiveConfigResource{}
+	for _, resource := range resources {
+		gv, err := schema.ParseGroupVersion(resource.APIVersion)
+		if err != nil {
+			return nil, nil, err
+		}
+		mapping, err := r.RESTMapper().RESTMapping(gv.WithKind(resource.Kind).GroupKind(), gv.Version)
+		if meta.IsNoMatchError(err) {
+			log.Info("Skipping resource not served by the cluster", "apiVersion", resource.APIVersion, "kind", resource.Kind)
+			skipped = append(skipped, resource)
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		mapped = append(mapped, mappedResource{KubeArchiveConfigResource: resource, mapping: mapping})
+	}
+	return mapped, skipped, nil
+}
+
+// updateResourcesCondition sets the ResourcesServed condition of kaconfig, so users can see which of its
+// resources are not archived because the cluster does not serve them.
+func (r *KubeArchiveConfigReconciler) updateResourcesCondition(ctx context.Context, kaconfig *kubearchivev1alpha1.KubeArchiveConfig, skipped []kubearchivev1alpha1.KubeArchiveConfigResource) error {
+	log := log.FromContext(ctx)
+
+	condition := metav1.Condition{
+		Type:               kubearchivev1alpha1.ResourcesServedCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "AllResourcesServed",
+		Message:            "All the resources are served by the cluster.",
+		ObservedGeneration: kaconfig.Generation,
+	}
+	if len(skipped) > 0 {
+		kinds := []string{}
+		for _, resource := range skipped {
+			kinds = append(kinds, fmt.Sprintf("%s %s", resource.APIVersion, resource.Kind))
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ResourcesNotServed"
+		condition.Message = fmt.Sprintf("Not archived until the cluster serves them: %s.", strings.Join(kinds, ", "))
+	}
+	if !meta.SetStatusCondition(&kaconfig.Status.Conditions, condition) {
+		return nil
+	}
+
+	if err := r.Status().Update(ctx, kaconfig); err != nil {
+		log.Error(err, "Failed to update KubeArchiveConfig status")
+		return err
+	}
+	return nil
+}
+
+func (r *KubeArchiveConfigReconciler) desiredRole(kaconfig *kubearchivev1alpha1.KubeArchiveConfig, resources []mappedResource) (*rbacv1.Role, error) {
+	rules := []rbacv1.PolicyRule{}
+	for _, resource := range resources {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{resource.mapping.GroupVersionKind.Group},
+			Resources: []string{resource.mapping.Resource.Resource},
+			Verbs:     []string{"get", "list", "watch"},
+		})
+	}
+
 	role := &rbacv1.Role{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      kaconfig.Name,
 			Namespace: kaconfig.Namespace,
 		},
-		Rules: []rbacv1.PolicyRule{{
-			APIGroups: []string{""},
-			Resources: []string{"events"},
-			Verbs:     []string{"get", "list", "watch"},
-		}},
+		Rules: rules,
 	}
 
 	if err := ctrl.SetControllerReference(kaconfig, role, r.Scheme); err != nil {
@@ -220,18 +410,21 @@ func (r *KubeArchiveConfigReconciler) desiredRoleBinding(kaconfig *kubearchivev1
 	return binding, nil
 }
 
-func (r *KubeArchiveConfigReconciler) reconcileApiServerSource(ctx context.Context, kaconfig *kubearchivev1alpha1.KubeArchiveConfig) (*sourcesv1.ApiServerSource, error) {
+func (r *KubeArchiveConfigReconciler) reconcileApiServerSource(ctx context.Context, kaconfig *kubearchivev1alpha1.KubeArchiveConfig, resources []mappedResource) (*sourcesv1.ApiServerSource, error) {
 	log := log.FromContext(ctx)
 
 	log.Info("in reconcileApiServerSource")
-	source, err := r.desiredApiServerSource(kaconfig)
+	source, err := r.desiredApiServerSource(kaconfig, resources)
 	if err != nil {
 		log.Error(err, "Unable to get desired ApiServerSource")
 		return source, err
 	}
 
-	err = r.Get(ctx, types.NamespacedName{Name: kaconfig.Name, Namespace: kaconfig.Namespace}, &sourcesv1.ApiServerSource{})
+	existing := &sourcesv1.ApiServerSource{}
+	err = r.Get(ctx, types.NamespacedName{Name: kaconfig.Name, Namespace: kaconfig.Namespace}, existing)
 	if err == nil {
+		// Custom resources do not allow unconditional updates
+		source.ResourceVersion = existing.ResourceVersion
 		err = r.Update(ctx, source)
 		if err != nil {
 			log.Error(err, "Failed to update ApiServerSource")
@@ -251,7 +444,36 @@ func (r *KubeArchiveConfigReconciler) reconcileApiServerSource(ctx context.Conte
 	return source, nil
 }
 
-func (r *KubeArchiveConfigReconciler) desiredApiServerSource(kaconfig *kubearchivev1alpha1.KubeArchiveConfig) (*sourcesv1.ApiServerSource, error) {
+// deleteApiServerSource removes the ApiServerSource of kaconfig, if any, so its namespace stops being archived
+func (r *KubeArchiveConfigReconciler) deleteApiServerSource(ctx context.Context, kaconfig *kubearchivev1alpha1.KubeArchiveConfig) error {
+	log := log.FromContext(ctx)
+
+	log.Info("in deleteApiServerSource")
+	source := &sourcesv1.ApiServerSource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kaconfig.Name,
+			Namespace: kaconfig.Namespace,
+		},
+	}
+	err := r.Delete(ctx, source)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to delete ApiServerSource")
+		return err
+	}
+
+	return nil
+}
+
+func (r *KubeArchiveConfigReconciler) desiredApiServerSource(kaconfig *kubearchivev1alpha1.KubeArchiveConfig, resources []mappedResource) (*sourcesv1.ApiServerSource, error) {
+	selectors := []sourcesv1.APIVersionKindSelector{}
+	for _, resource := range resources {
+		selectors = append(selectors, sourcesv1.APIVersionKindSelector{
+			APIVersion:    resource.APIVersion,
+			Kind:          resource.Kind,
+			LabelSelector: resource.LabelSelector.DeepCopy(),
+		})
+	}
+
 	source := &sourcesv1.ApiServerSource{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "ApiServerSource",
@@ -264,17 +486,14 @@ func (r *KubeArchiveConfigReconciler) desiredApiServerSource(kaconfig *kubearchi
 		Spec: sourcesv1.ApiServerSourceSpec{
 			EventMode:          "Resource",
 			ServiceAccountName: kaconfig.Name,
-			Resources: []sourcesv1.APIVersionKindSelector{{
-				APIVersion: "v1",
-				Kind:       "Event",
-			}},
+			Resources:          selectors,
 			SourceSpec: duckv1.SourceSpec{
 				Sink: duckv1.Destination{
 					Ref: &duckv1.KReference{
 						APIVersion: "v1",
 						Kind:       "Service",
-						Name:       "kubearchive-sink",
-						Namespace:  "kubearchive",
+						Name:       r.sinkName(),
+						Namespace:  r.sinkNamespace(),
 					},
 				},
 			},
@@ -8,8 +8,13 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -68,4 +73,252 @@ var _ = Describe("KubeArchiveConfig Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When several KubeArchive installations share the cluster", func() {
+		const resourceName = "test-instance"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			resource := &kubearchivev1alpha1.KubeArchiveConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					Labels:    map[string]string{kubearchivev1alpha1.InstanceLabel: "second"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kubearchivev1alpha1.KubeArchiveConfig{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should only be reconciled by its own installation", func() {
+			first := &KubeArchiveConfigReconciler{
+				Client:        k8sClient,
+				Scheme:        k8sClient.Scheme(),
+				SinkName:      "first-sink",
+				SinkNamespace: "first",
+				Instance:      "first",
+			}
+			second := &KubeArchiveConfigReconciler{
+				Client:        k8sClient,
+				Scheme:        k8sClient.Scheme(),
+				SinkName:      "second-sink",
+				SinkNamespace: "second",
+				Instance:      "second",
+			}
+			request := reconcile.Request{NamespacedName: typeNamespacedName}
+
+			By("Reconciling with the other installation")
+			_, err := first.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, typeNamespacedName, &sourcesv1.ApiServerSource{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("Reconciling with its own installation")
+			_, err = second.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reconciling with the other installation again")
+			_, err = first.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			source := &sourcesv1.ApiServerSource{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, source)).To(Succeed())
+			Expect(source.Spec.Sink.Ref.Name).To(Equal("second-sink"))
+			Expect(source.Spec.Sink.Ref.Namespace).To(Equal("second"))
+			Expect(second.sources.sources).To(HaveKey(typeNamespacedName))
+
+			By("Handing it over to the other installation")
+			kaconfig := &kubearchivev1alpha1.KubeArchiveConfig{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, kaconfig)).To(Succeed())
+			kaconfig.Labels[kubearchivev1alpha1.InstanceLabel] = "first"
+			Expect(k8sClient.Update(ctx, kaconfig)).To(Succeed())
+			_, err = second.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second.sources.sources).NotTo(HaveKey(typeNamespacedName))
+		})
+	})
+
+	Context("When pausing a KubeArchiveConfig", func() {
+		const resourceName = "test-paused"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			resource := &kubearchivev1alpha1.KubeArchiveConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kubearchivev1alpha1.KubeArchiveConfig{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should remove the ApiServerSource until it is resumed", func() {
+			controllerReconciler := &KubeArchiveConfigReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			reconcileConfig := func() {
+				result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: typeNamespacedName,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+			}
+			setPaused := func(paused bool) {
+				resource := &kubearchivev1alpha1.KubeArchiveConfig{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				if paused {
+					resource.Annotations = map[string]string{kubearchivev1alpha1.PausedAnnotation: "true"}
+				} else {
+					delete(resource.Annotations, kubearchivev1alpha1.PausedAnnotation)
+				}
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			}
+
+			By("Reconciling the created resource")
+			reconcileConfig()
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &sourcesv1.ApiServerSource{})).To(Succeed())
+
+			By("Pausing the resource")
+			setPaused(true)
+			reconcileConfig()
+			err := k8sClient.Get(ctx, typeNamespacedName, &sourcesv1.ApiServerSource{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &corev1.ServiceAccount{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &rbacv1.Role{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &rbacv1.RoleBinding{})).To(Succeed())
+
+			By("Resuming the resource")
+			setPaused(false)
+			reconcileConfig()
+			Expect(k8sClient.Get(ctx, typeNamespacedName, &sourcesv1.ApiServerSource{})).To(Succeed())
+		})
+	})
+
+	Context("When archiving kinds the cluster does not serve", func() {
+		const resourceName = "test-unserved"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			resource := &kubearchivev1alpha1.KubeArchiveConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				// The Tekton CRDs are not installed in the test environment
+				Spec: kubearchivev1alpha1.KubeArchiveConfigSpec{Preset: "tekton"},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &kubearchivev1alpha1.KubeArchiveConfig{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should skip them and archive the rest", func() {
+			controllerReconciler := &KubeArchiveConfigReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(unservedResourcesRequeue))
+
+			kaconfig := &kubearchivev1alpha1.KubeArchiveConfig{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, kaconfig)).To(Succeed())
+			condition := apimeta.FindStatusCondition(kaconfig.Status.Conditions, kubearchivev1alpha1.ResourcesServedCondition)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Message).To(ContainSubstring("tekton.dev/v1 PipelineRun"))
+
+			role := &rbacv1.Role{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, role)).To(Succeed())
+			Expect(role.Rules).To(ConsistOf(
+				rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"get", "list", "watch"}},
+				rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}},
+			))
+
+			source := &sourcesv1.ApiServerSource{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, source)).To(Succeed())
+			Expect(source.Spec.Resources).To(ConsistOf(
+				sourcesv1.APIVersionKindSelector{APIVersion: "v1", Kind: "Event"},
+				sourcesv1.APIVersionKindSelector{APIVersion: "v1", Kind: "Pod"},
+			))
+		})
+	})
+})
+
+var _ = Describe("KubeArchiveConfig installation", func() {
+	DescribeTable("should only own the KubeArchiveConfigs of its instance",
+		func(instance string, labels map[string]string, expected bool) {
+			r := &KubeArchiveConfigReconciler{Instance: instance}
+			kaconfig := &kubearchivev1alpha1.KubeArchiveConfig{ObjectMeta: metav1.ObjectMeta{Labels: labels}}
+			Expect(r.ownsConfig(kaconfig)).To(Equal(expected))
+		},
+		Entry("default instance, no label", "", nil, true),
+		Entry("default instance, labeled", "", map[string]string{kubearchivev1alpha1.InstanceLabel: "other"}, false),
+		Entry("named instance, no label", "test", nil, false),
+		Entry("named instance, same label", "test", map[string]string{kubearchivev1alpha1.InstanceLabel: "test"}, true),
+		Entry("named instance, other label", "test", map[string]string{kubearchivev1alpha1.InstanceLabel: "other"}, false),
+	)
+
+	It("should see KubeArchiveConfigs relabeled to another instance", func() {
+		r := &KubeArchiveConfigReconciler{Instance: "test"}
+		owned := &kubearchivev1alpha1.KubeArchiveConfig{ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{kubearchivev1alpha1.InstanceLabel: "test"},
+		}}
+		other := &kubearchivev1alpha1.KubeArchiveConfig{ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{kubearchivev1alpha1.InstanceLabel: "other"},
+		}}
+
+		p := r.instancePredicate()
+		Expect(p.Update(event.UpdateEvent{ObjectOld: owned, ObjectNew: other})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: other, ObjectNew: owned})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: other, ObjectNew: other})).To(BeFalse())
+		Expect(p.Create(event.CreateEvent{Object: other})).To(BeFalse())
+	})
+
+	It("should default the sink when it is not set", func() {
+		r := &KubeArchiveConfigReconciler{}
+		Expect(r.sinkName()).To(Equal(DefaultSinkName))
+		Expect(r.sinkNamespace()).To(Equal(DefaultSinkNamespace))
+
+		r = &KubeArchiveConfigReconciler{SinkName: "sink", SinkNamespace: "test"}
+		Expect(r.sinkName()).To(Equal("sink"))
+		Expect(r.sinkNamespace()).To(Equal("test"))
+	})
 })
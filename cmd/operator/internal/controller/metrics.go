@@ -0,0 +1,56 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Reconcile durations, reconcile error counts per controller and workqueue depth are already exposed by
+// controller-runtime (controller_runtime_reconcile_time_seconds, controller_runtime_reconcile_errors_total and
+// workqueue_depth). The metrics below cover what controller-runtime cannot know about.
+var (
+	activeSourcesGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "kubearchive",
+		Subsystem: "operator",
+		Name:      "active_sources",
+		Help:      "Number of KubeArchiveConfigs whose ApiServerSource was successfully reconciled.",
+	})
+	resourceReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kubearchive",
+		Subsystem: "operator",
+		Name:      "resource_reconcile_errors_total",
+		Help:      "Number of errors reconciling resources owned by a KubeArchiveConfig.",
+	}, []string{"controller", "resource"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(activeSourcesGauge, resourceReconcileErrors)
+}
+
+// activeSources tracks which KubeArchiveConfigs have a working ApiServerSource, so activeSourcesGauge
+// can drop when a KubeArchiveConfig is deleted or its source stops reconciling.
+type activeSources struct {
+	mu      sync.Mutex
+	sources map[types.NamespacedName]struct{}
+}
+
+func (a *activeSources) set(name types.NamespacedName, active bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.sources == nil {
+		a.sources = map[types.NamespacedName]struct{}{}
+	}
+	if active {
+		a.sources[name] = struct{}{}
+	} else {
+		delete(a.sources, name)
+	}
+	activeSourcesGauge.Set(float64(len(a.sources)))
+}
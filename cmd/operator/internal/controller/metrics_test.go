@@ -0,0 +1,31 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Active sources", func() {
+	It("should count each KubeArchiveConfig with an active source once", func() {
+		sources := &activeSources{}
+		first := types.NamespacedName{Name: "first", Namespace: "test"}
+		second := types.NamespacedName{Name: "second", Namespace: "test"}
+
+		sources.set(first, true)
+		sources.set(first, true)
+		sources.set(second, true)
+		Expect(testutil.ToFloat64(activeSourcesGauge)).To(Equal(2.0))
+
+		sources.set(first, false)
+		Expect(testutil.ToFloat64(activeSourcesGauge)).To(Equal(1.0))
+
+		sources.set(first, false)
+		sources.set(second, false)
+		Expect(testutil.ToFloat64(activeSourcesGauge)).To(Equal(0.0))
+	})
+})
@@ -0,0 +1,63 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubearchivev1alpha1 "github.com/kubearchive/kubearchive/cmd/operator/api/v1alpha1"
+)
+
+// defaultResources are archived for every KubeArchiveConfig
+var defaultResources = []kubearchivev1alpha1.KubeArchiveConfigResource{
+	{APIVersion: "v1", Kind: "Event"},
+}
+
+// presets maps the values accepted by KubeArchiveConfigSpec.Preset to the resources they archive
+var presets = map[string][]kubearchivev1alpha1.KubeArchiveConfigResource{
+	"tekton": {
+		{APIVersion: "tekton.dev/v1", Kind: "PipelineRun"},
+		{APIVersion: "tekton.dev/v1", Kind: "TaskRun"},
+		{APIVersion: "v1", Kind: "Pod"},
+	},
+	"argo-workflows": {
+		{APIVersion: "argoproj.io/v1alpha1", Kind: "Workflow"},
+		{APIVersion: "argoproj.io/v1alpha1", Kind: "CronWorkflow"},
+		{APIVersion: "v1", Kind: "Pod"},
+	},
+	"batch-jobs": {
+		{APIVersion: "batch/v1", Kind: "Job"},
+		{APIVersion: "batch/v1", Kind: "CronJob"},
+		{APIVersion: "v1", Kind: "Pod"},
+	},
+}
+
+// archivedResources returns the resources archived for kaconfig: the default resources, the resources of
+// its preset and the resources listed in its spec, without duplicates. The same kind listed with different
+// label selectors is kept once per selector.
+func archivedResources(kaconfig *kubearchivev1alpha1.KubeArchiveConfig) ([]kubearchivev1alpha1.KubeArchiveConfigResource, error) {
+	resources := append([]kubearchivev1alpha1.KubeArchiveConfigResource{}, defaultResources...)
+	if kaconfig.Spec.Preset != "" {
+		preset, ok := presets[kaconfig.Spec.Preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %q", kaconfig.Spec.Preset)
+		}
+		resources = append(resources, preset...)
+	}
+	resources = append(resources, kaconfig.Spec.Resources...)
+
+	seen := map[string]bool{}
+	unique := []kubearchivev1alpha1.KubeArchiveConfigResource{}
+	for _, resource := range resources {
+		key := fmt.Sprintf("%s/%s/%s", resource.APIVersion, resource.Kind,
+			metav1.FormatLabelSelector(resource.LabelSelector))
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, resource)
+		}
+	}
+	return unique, nil
+}
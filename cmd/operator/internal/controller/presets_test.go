@@ -0,0 +1,69 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubearchivev1alpha1 "github.com/kubearchive/kubearchive/cmd/operator/api/v1alpha1"
+)
+
+var _ = Describe("KubeArchiveConfig presets", func() {
+	It("should archive only the default resources when no preset is set", func() {
+		resources, err := archivedResources(&kubearchivev1alpha1.KubeArchiveConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(Equal(defaultResources))
+	})
+
+	It("should expand the preset and merge it with the listed resources", func() {
+		kaconfig := &kubearchivev1alpha1.KubeArchiveConfig{
+			Spec: kubearchivev1alpha1.KubeArchiveConfigSpec{
+				Preset: "batch-jobs",
+				Resources: []kubearchivev1alpha1.KubeArchiveConfigResource{
+					{APIVersion: "v1", Kind: "Pod"},
+					{APIVersion: "v1", Kind: "ConfigMap"},
+				},
+			},
+		}
+		resources, err := archivedResources(kaconfig)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(Equal([]kubearchivev1alpha1.KubeArchiveConfigResource{
+			{APIVersion: "v1", Kind: "Event"},
+			{APIVersion: "batch/v1", Kind: "Job"},
+			{APIVersion: "batch/v1", Kind: "CronJob"},
+			{APIVersion: "v1", Kind: "Pod"},
+			{APIVersion: "v1", Kind: "ConfigMap"},
+		}))
+	})
+
+	It("should keep the same kind once per label selector", func() {
+		ci := &metav1.LabelSelector{MatchLabels: map[string]string{"ci": "true"}}
+		kaconfig := &kubearchivev1alpha1.KubeArchiveConfig{
+			Spec: kubearchivev1alpha1.KubeArchiveConfigSpec{
+				Resources: []kubearchivev1alpha1.KubeArchiveConfigResource{
+					{APIVersion: "batch/v1", Kind: "Job", LabelSelector: ci},
+					{APIVersion: "batch/v1", Kind: "Job", LabelSelector: ci.DeepCopy()},
+					{APIVersion: "batch/v1", Kind: "Job"},
+				},
+			},
+		}
+		resources, err := archivedResources(kaconfig)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(Equal([]kubearchivev1alpha1.KubeArchiveConfigResource{
+			{APIVersion: "v1", Kind: "Event"},
+			{APIVersion: "batch/v1", Kind: "Job", LabelSelector: ci},
+			{APIVersion: "batch/v1", Kind: "Job"},
+		}))
+	})
+
+	It("should fail on an unknown preset", func() {
+		kaconfig := &kubearchivev1alpha1.KubeArchiveConfig{
+			Spec: kubearchivev1alpha1.KubeArchiveConfigSpec{Preset: "unknown"},
+		}
+		_, err := archivedResources(kaconfig)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -6,6 +6,7 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -41,12 +42,24 @@ func init() {
 	//+kubebuilder:scaffold:scheme
 }
 
+// leaderElectionID returns the leader election lease name of the given installation, so the operators of
+// different installations do not wait on each other.
+func leaderElectionID(instance string) string {
+	if instance == "" {
+		return "e7a70c64.kubearchive.org"
+	}
+	return fmt.Sprintf("%s.e7a70c64.kubearchive.org", instance)
+}
+
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var sinkName string
+	var sinkNamespace string
+	var instance string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -56,6 +69,12 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&sinkName, "sink-name", controller.DefaultSinkName, "The name of the sink Service events are sent to.")
+	flag.StringVar(&sinkNamespace, "sink-namespace", controller.DefaultSinkNamespace,
+		"The namespace of the sink Service events are sent to.")
+	flag.StringVar(&instance, "instance", "",
+		"The name of this KubeArchive installation. Only KubeArchiveConfigs with a matching kubearchive.org/instance "+
+			"label are reconciled, so several installations can share a cluster.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -94,7 +113,7 @@ func main() {
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "e7a70c64.kubearchive.org",
+		LeaderElectionID:       leaderElectionID(instance),
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -113,8 +132,11 @@ func main() {
 	}
 
 	if err = (&controller.KubeArchiveConfigReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		SinkName:      sinkName,
+		SinkNamespace: sinkNamespace,
+		Instance:      instance,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KubeArchiveConfig")
 		os.Exit(1)
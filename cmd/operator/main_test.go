@@ -0,0 +1,25 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaderElectionID(t *testing.T) {
+	tests := []struct {
+		instance string
+		expected string
+	}{
+		{instance: "", expected: "e7a70c64.kubearchive.org"},
+		{instance: "test", expected: "test.e7a70c64.kubearchive.org"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.instance, func(t *testing.T) {
+			assert.Equal(t, tc.expected, leaderElectionID(tc.instance))
+		})
+	}
+}
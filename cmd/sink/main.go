@@ -12,13 +12,36 @@ import (
 	ceOtelObs "github.com/cloudevents/sdk-go/observability/opentelemetry/v2/client"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	ceClient "github.com/cloudevents/sdk-go/v2/client"
+	"github.com/cloudevents/sdk-go/v2/extensions"
 	kaObservability "github.com/kubearchive/kubearchive/pkg/observability"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var logger = log.New(os.Stderr, "", log.LstdFlags|log.Lmicroseconds|log.LUTC)
 
-func receive(event cloudevents.Event) {
+// eventContext returns ctx with the trace context carried by event, if any. This continues the trace started by the
+// producer of the event instead of the one of the HTTP request that delivered it, which may come from a broker.
+func eventContext(ctx context.Context, event cloudevents.Event) context.Context {
+	if _, ok := extensions.GetDistributedTracingExtension(event); !ok {
+		return ctx
+	}
+	return ceOtelObs.ExtractDistributedTracingExtension(ctx, event)
+}
+
+func receive(ctx context.Context, event cloudevents.Event) {
+	_, span := otel.Tracer("kubearchive.sink").Start(
+		eventContext(ctx, event), "archive",
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+		trace.WithAttributes(
+			attribute.String("cloudevents.event_id", event.ID()),
+			attribute.String("cloudevents.event_type", event.Type()),
+		),
+	)
+	defer span.End()
+
 	logger.Println("received CloudEvent: ", event.ID())
 	logger.Printf("%s\n", event.String())
 }
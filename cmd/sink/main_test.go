@@ -0,0 +1,36 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent = "00-" + traceID + "-00f067aa0ba902b7-01"
+)
+
+func TestEventContext(t *testing.T) {
+	event := cloudevents.NewEvent()
+	event.SetExtension("traceparent", traceparent)
+
+	ctx := eventContext(context.Background(), event)
+	spanContext := trace.SpanContextFromContext(ctx)
+	assert.True(t, spanContext.IsValid())
+	assert.True(t, spanContext.IsRemote())
+	assert.Equal(t, traceID, spanContext.TraceID().String())
+}
+
+func TestEventContextWithoutExtension(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	assert.Equal(t, ctx, eventContext(ctx, cloudevents.NewEvent()))
+}
@@ -9,36 +9,74 @@ import (
 	"fmt"
 	_ "github.com/lib/pq"
 	"os"
+	"strings"
 )
 
 type flags struct {
-	DatabaseName     string
-	DatabaseUser     string
-	DatabasePassword string
+	DatabaseHost        string
+	DatabasePort        int
+	DatabaseName        string
+	DatabaseUser        string
+	DatabasePassword    string
+	DatabaseSSLMode     string
+	DatabaseSSLRootCert string
+	DatabaseSSLCert     string
+	DatabaseSSLKey      string
 }
 
 var defaultValues = &flags{
+	DatabaseHost:     "localhost",
+	DatabasePort:     5432,
 	DatabaseName:     "postgresdb",
 	DatabaseUser:     "ps_user",
 	DatabasePassword: "P0stgr3sdbP@ssword", // notsecret
+	DatabaseSSLMode:  "disable",
 }
 
-const (
-	host = "localhost"
-	port = 5432
-)
+// quoteValue quotes v as a lib/pq connection string value, so values with spaces, quotes or backslashes,
+// like passwords or paths, are read back as they are.
+func quoteValue(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
+
+// connectionString builds the lib/pq connection string for the given flags. Empty TLS
+// settings are left out so the driver defaults apply.
+func connectionString(f flags) string {
+	params := []string{
+		fmt.Sprintf("host=%s", quoteValue(f.DatabaseHost)),
+		fmt.Sprintf("port=%d", f.DatabasePort),
+		fmt.Sprintf("user=%s", quoteValue(f.DatabaseUser)),
+		fmt.Sprintf("password=%s", quoteValue(f.DatabasePassword)),
+		fmt.Sprintf("dbname=%s", quoteValue(f.DatabaseName)),
+		fmt.Sprintf("sslmode=%s", quoteValue(f.DatabaseSSLMode)),
+	}
+	if f.DatabaseSSLRootCert != "" {
+		params = append(params, fmt.Sprintf("sslrootcert=%s", quoteValue(f.DatabaseSSLRootCert)))
+	}
+	if f.DatabaseSSLCert != "" {
+		params = append(params, fmt.Sprintf("sslcert=%s", quoteValue(f.DatabaseSSLCert)))
+	}
+	if f.DatabaseSSLKey != "" {
+		params = append(params, fmt.Sprintf("sslkey=%s", quoteValue(f.DatabaseSSLKey)))
+	}
+	return strings.Join(params, " ")
+}
 
 func main() {
 	var flagValues flags
+	flag.StringVar(&flagValues.DatabaseHost, "database-host", defaultValues.DatabaseHost, "PostgreSQL host")
+	flag.IntVar(&flagValues.DatabasePort, "database-port", defaultValues.DatabasePort, "PostgreSQL port")
 	flag.StringVar(&flagValues.DatabaseName, "database-name", defaultValues.DatabaseName, "PostgreSQL database name")
 	flag.StringVar(&flagValues.DatabaseUser, "database-user", defaultValues.DatabaseUser, "PostgreSQL database user")
 	flag.StringVar(&flagValues.DatabasePassword, "database-password", defaultValues.DatabasePassword, "PostgreSQL database password")
+	flag.StringVar(&flagValues.DatabaseSSLMode, "database-sslmode", defaultValues.DatabaseSSLMode, "PostgreSQL SSL mode: disable, require, verify-ca or verify-full")
+	flag.StringVar(&flagValues.DatabaseSSLRootCert, "database-sslrootcert", "", "Path to the CA bundle used to verify the PostgreSQL server certificate")
+	flag.StringVar(&flagValues.DatabaseSSLCert, "database-sslcert", "", "Path to the client certificate used to authenticate to PostgreSQL")
+	flag.StringVar(&flagValues.DatabaseSSLKey, "database-sslkey", "", "Path to the key of the client certificate")
 	flag.Parse()
 
 	// connect to the DB.
-	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s "+
-		"password=%s dbname=%s sslmode=disable",
-		host, port, flagValues.DatabaseUser, flagValues.DatabasePassword, flagValues.DatabaseName)
+	psqlInfo := connectionString(flagValues)
 
 	// postgres is the driver type.
 	db, err := sql.Open("postgres", psqlInfo)
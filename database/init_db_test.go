@@ -0,0 +1,61 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionString(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    flags
+		expected string
+	}{
+		{
+			name:     "default values",
+			given:    *defaultValues,
+			expected: "host='localhost' port=5432 user='ps_user' password='P0stgr3sdbP@ssword' dbname='postgresdb' sslmode='disable'", // notsecret
+		},
+		{
+			name: "password with spaces, quotes and backslashes",
+			given: flags{
+				DatabaseHost:     "db.example.com",
+				DatabasePort:     5433,
+				DatabaseName:     "kubearchive",
+				DatabaseUser:     "kubearchive",
+				DatabasePassword: `a b'c\d`,
+				DatabaseSSLMode:  "disable",
+			},
+			expected: `host='db.example.com' port=5433 user='kubearchive' password='a b\'c\\d' dbname='kubearchive' sslmode='disable'`,
+		},
+		{
+			name: "tls files",
+			given: flags{
+				DatabaseHost:        "localhost",
+				DatabasePort:        5432,
+				DatabaseName:        "postgresdb",
+				DatabaseUser:        "ps_user",
+				DatabasePassword:    "password",
+				DatabaseSSLMode:     "verify-full",
+				DatabaseSSLRootCert: "/etc/ssl/my ca.crt",
+				DatabaseSSLCert:     "/etc/ssl/tls.crt",
+				DatabaseSSLKey:      "/etc/ssl/tls.key",
+			},
+			expected: "host='localhost' port=5432 user='ps_user' password='password' dbname='postgresdb' sslmode='verify-full' " +
+				"sslrootcert='/etc/ssl/my ca.crt' sslcert='/etc/ssl/tls.crt' sslkey='/etc/ssl/tls.key'",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dsn := connectionString(tc.given)
+			assert.Equal(t, tc.expected, dsn)
+			_, err := pq.NewConnector(dsn)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -19,16 +20,24 @@ import (
 // the name of the environment variable that will determine if instrumentation needs to be started
 const OtelStartEnvVar = "KUBEARCHIVE_OTEL_ENABLED"
 
+// the names of the standard OpenTelemetry environment variables that configure where traces are exported to
+const (
+	otlpEndpointEnvVar       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	otlpTracesEndpointEnvVar = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+)
+
 var tp *sdkTrace.TracerProvider
 
 // Start creates a Span Processor and exporter, registers them with a TracerProvider, and sets the default
-// TracerProvider and SetTextMapPropagator
+// TracerProvider and SetTextMapPropagator. The sampler is configured with the standard OTEL_TRACES_SAMPLER and
+// OTEL_TRACES_SAMPLER_ARG environment variables, and resource attributes such as the cluster name with
+// OTEL_RESOURCE_ATTRIBUTES.
 func Start() error {
 	if canSkipInit() {
 		return nil
 	}
 
-	exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+	exporter, err := newExporter(context.Background())
 	if err != nil {
 		return err
 	}
@@ -55,6 +64,16 @@ func Start() error {
 	return nil
 }
 
+// newExporter returns an OTLP over HTTP exporter if an OTLP endpoint is configured, for all signals or just for
+// traces. Otherwise it returns an exporter that prints spans to stdout. The OTLP exporter reads the rest of its
+// configuration, like headers or timeouts, from the standard OTEL_EXPORTER_OTLP_* environment variables.
+func newExporter(ctx context.Context) (sdkTrace.SpanExporter, error) {
+	if os.Getenv(otlpEndpointEnvVar) != "" || os.Getenv(otlpTracesEndpointEnvVar) != "" {
+		return otlptracehttp.New(ctx)
+	}
+	return stdouttrace.New(stdouttrace.WithPrettyPrint())
+}
+
 // canSkipInit returns a bool representing if OtelStartEnvVar is set to false. This function is a helper for Start.
 // Instrumentation should *ONLY* be started if this function returns false
 func canSkipInit() bool {
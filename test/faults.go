@@ -0,0 +1,84 @@
+// Copyright KubeArchive Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// KillPods deletes the pods matching selector in namespace without a grace period, simulating a crash of the
+// containers in them.
+func KillPods(client kubernetes.Interface, namespace string, selector string) error {
+	gracePeriod := int64(0)
+	return client.CoreV1().Pods(namespace).DeleteCollection(
+		context.Background(),
+		metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod},
+		metav1.ListOptions{LabelSelector: selector},
+	)
+}
+
+// RestartDeployment triggers a rollout of the deployment, the same way kubectl rollout restart does.
+func RestartDeployment(client kubernetes.Interface, namespace string, name string) error {
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":"%s"}}}}}`,
+		time.Now().Format(time.RFC3339))
+	_, err := client.AppsV1().Deployments(namespace).Patch(
+		context.Background(), name, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// PartitionPods cuts all the network traffic from and to the pods matching selector in namespace with a
+// NetworkPolicy. The returned function removes the NetworkPolicy. The cluster CNI must enforce NetworkPolicies
+// for the partition to have any effect.
+func PartitionPods(client kubernetes.Interface, namespace string, selector map[string]string) (func() error, error) {
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("partition-%s", RandomString()),
+			Namespace: namespace,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: selector},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
+	}
+	policies := client.NetworkingV1().NetworkPolicies(namespace)
+	_, err := policies.Create(context.Background(), policy, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return policies.Delete(context.Background(), policy.Name, metav1.DeleteOptions{})
+	}, nil
+}
+
+// WaitForDeployment waits until all the replicas of the deployment are updated and available, or timeout passes.
+func WaitForDeployment(client kubernetes.Interface, namespace string, name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		deployment, err := client.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		if deployment.Status.ObservedGeneration >= deployment.Generation &&
+			deployment.Status.UpdatedReplicas == replicas &&
+			deployment.Status.AvailableReplicas == replicas {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for deployment %s/%s to be ready", namespace, name)
+		}
+		time.Sleep(3 * time.Second)
+	}
+}
@@ -12,8 +12,11 @@ import (
 	"github.com/kubearchive/kubearchive/test"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
+const deploymentTimeout = 30 * time.Second
+
 func TestMain(m *testing.M) {
 	if os.Getenv("KO_DOCKER_REPO") == "" {
 		os.Setenv("KO_DOCKER_REPO", "kind.local")
@@ -21,7 +24,9 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-func TestSomething(t *testing.T) {
+// deploySink deploys the sink in a new namespace and waits for it to be ready. It returns the name of the namespace
+// and a client for the cluster.
+func deploySink(t *testing.T) (string, kubernetes.Interface) {
 	namespaceName := fmt.Sprintf("test-%s", test.RandomString())
 	namespace := fmt.Sprintf(`
 ---
@@ -60,32 +65,80 @@ spec:
 	if err != nil {
 		t.Fatal(err)
 	}
+	t.Cleanup(func() {
+		err := test.DeleteResources(namespace)
+		if err != nil {
+			panic(err)
+		}
+	})
 
 	client, err := test.GetKubernetesClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = test.WaitForDeployment(client, namespaceName, "kubearchive", deploymentTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return namespaceName, client
+}
+
+func TestSomething(t *testing.T) {
+	deploySink(t)
+}
 
-	acc := 0.0
-	for {
-		if acc >= 30 {
-			t.Fatal("Timed out waiting for deployment to be ready.")
+func TestSinkFaults(t *testing.T) {
+	namespace, client := deploySink(t)
+
+	t.Run("pods killed", func(t *testing.T) {
+		err := test.KillPods(client, namespace, "app.kubernetes.io/name=kubearchive")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = test.WaitForDeployment(client, namespace, "kubearchive", deploymentTimeout)
+		if err != nil {
+			t.Fatal(err)
 		}
+	})
 
-		deploymentResource, err := client.AppsV1().Deployments(namespaceName).Get(context.Background(), "kubearchive", metav1.GetOptions{})
+	t.Run("deployment restarted", func(t *testing.T) {
+		err := test.RestartDeployment(client, namespace, "kubearchive")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = test.WaitForDeployment(client, namespace, "kubearchive", deploymentTimeout)
 		if err != nil {
 			t.Fatal(err)
 		}
+	})
 
-		if deploymentResource.Status.AvailableReplicas == 1 {
-			break
-		} else {
-			time.Sleep(3 * time.Second)
-			acc += 3
+	t.Run("network partition", func(t *testing.T) {
+		heal, err := test.PartitionPods(client, namespace, map[string]string{"app.kubernetes.io/name": "kubearchive"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		policies, err := client.NetworkingV1().NetworkPolicies(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(policies.Items) != 1 {
+			t.Fatalf("expected 1 NetworkPolicy partitioning the sink, found %d", len(policies.Items))
 		}
-	}
 
-	t.Cleanup(func() {
-		err := test.DeleteResources(namespace)
+		err = heal()
 		if err != nil {
-			panic(err)
+			t.Fatal(err)
+		}
+		policies, err = client.NetworkingV1().NetworkPolicies(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(policies.Items) != 0 {
+			t.Fatalf("expected the partition to be healed, found %d NetworkPolicies", len(policies.Items))
+		}
+		err = test.WaitForDeployment(client, namespace, "kubearchive", deploymentTimeout)
+		if err != nil {
+			t.Fatal(err)
 		}
 	})
 }